@@ -0,0 +1,18 @@
+package shalm
+
+import "go.starlark.net/starlark"
+
+// builtins collects the Starlark globals contributed by this package's
+// subsystems (jewel backends, validate, discovery); each contributes its
+// entries from an init().
+var builtins = starlark.StringDict{}
+
+func registerBuiltin(name string, fn func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)) {
+	builtins[name] = starlark.NewBuiltin(name, fn)
+}
+
+// Builtins returns the Starlark globals contributed by this package, for
+// merging into the predeclared environment charts execute in.
+func Builtins() starlark.StringDict {
+	return builtins
+}
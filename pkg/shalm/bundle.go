@@ -0,0 +1,282 @@
+package shalm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+const (
+	cnabActionInstall   = "install"
+	cnabActionUninstall = "uninstall"
+	cnabActionUpgrade   = "upgrade"
+)
+
+type cnabParameter struct {
+	Definition string `json:"definition"`
+}
+
+type cnabCredential struct {
+	Path string `json:"path"`
+}
+
+type cnabAction struct {
+	Modifies bool `json:"modifies"`
+}
+
+// cnabBundle is the subset of the CNAB bundle.json schema shalm populates
+// when exporting a chart.
+type cnabBundle struct {
+	SchemaVersion string                    `json:"schemaVersion"`
+	Name          string                    `json:"name"`
+	Version       string                    `json:"version"`
+	Parameters    map[string]cnabParameter  `json:"parameters,omitempty"`
+	Credentials   map[string]cnabCredential `json:"credentials,omitempty"`
+	Actions       map[string]cnabAction     `json:"actions"`
+	Images        []string                  `json:"images,omitempty"`
+}
+
+// ociImageLayout is the "oci-layout" file of an OCI image layout.
+type ociImageLayout struct {
+	ImageLayoutVersion string `json:"imageLayoutVersion"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociIndex is the "index.json" file of an OCI image layout, one descriptor
+// per pinned image reference found in the chart's values.
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+func (c *chartImpl) eachJewel(block func(name string, j *jewel) error) error {
+	for name, v := range c.values {
+		j, ok := v.(*jewel)
+		if !ok {
+			continue
+		}
+		if err := block(name, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageRefs returns the pinned image references declared in the chart's
+// values: any string-valued key named "image" or ending in "Image"/"_image",
+// sorted for reproducible output.
+func (c *chartImpl) imageRefs() []string {
+	var refs []string
+	for k, v := range c.values {
+		s, ok := v.(starlark.String)
+		if !ok {
+			continue
+		}
+		if k == "image" || strings.HasSuffix(k, "Image") || strings.HasSuffix(k, "_image") {
+			refs = append(refs, string(s))
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// Bundle packages the chart as a CNAB-compliant thick bundle under writer.
+func (c *chartImpl) Bundle(writer io.Writer) error {
+	gz := gzip.NewWriter(writer)
+	tw := tar.NewWriter(gz)
+
+	images := c.imageRefs()
+	bundle := cnabBundle{
+		SchemaVersion: "1.0.0",
+		Name:          c.GetName(),
+		Version:       c.GetVersionString(),
+		Parameters:    map[string]cnabParameter{},
+		Credentials:   map[string]cnabCredential{},
+		Images:        images,
+		Actions: map[string]cnabAction{
+			cnabActionInstall:   {Modifies: true},
+			cnabActionUninstall: {Modifies: true},
+			cnabActionUpgrade:   {Modifies: true},
+		},
+	}
+	for k := range plainValues(c.values) {
+		bundle.Parameters[k] = cnabParameter{Definition: k}
+	}
+	if err := c.eachJewel(func(name string, j *jewel) error {
+		for key := range j.backend.Keys() {
+			credName := name + "." + key
+			bundle.Credentials[credName] = cnabCredential{Path: "/cnab/app/credentials/" + credName}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarEntry(tw, "bundle.json", data); err != nil {
+		return err
+	}
+
+	if err := c.bundleTree(tw, "app"); err != nil {
+		return err
+	}
+	if err := c.eachSubChart(func(subChart *chartImpl) error {
+		return subChart.bundleTree(tw, filepath.ToSlash(filepath.Join("app", "subcharts", subChart.GetName())))
+	}); err != nil {
+		return err
+	}
+
+	if err := writeImageLayout(tw, images); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// bundleTree writes the chart's source tree into the tar under prefix.
+func (c *chartImpl) bundleTree(tw *tar.Writer, prefix string) error {
+	return c.walk(func(name string, size int64, body io.Reader, err error) error {
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		return writeTarEntry(tw, filepath.ToSlash(filepath.Join(prefix, name)), content)
+	})
+}
+
+// writeImageLayout writes a minimal OCI image layout under artifacts/, one
+// descriptor per pinned image reference.
+func writeImageLayout(tw *tar.Writer, refs []string) error {
+	layout, err := json.Marshal(ociImageLayout{ImageLayoutVersion: "1.0.0"})
+	if err != nil {
+		return err
+	}
+	if err := writeTarEntry(tw, "artifacts/oci-layout", layout); err != nil {
+		return err
+	}
+
+	index := ociIndex{SchemaVersion: 2}
+	for _, ref := range refs {
+		index.Manifests = append(index.Manifests, ociDescriptor{
+			MediaType:   "application/vnd.oci.image.manifest.v1+json",
+			Digest:      fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(ref))),
+			Annotations: map[string]string{"org.opencontainers.image.ref.name": ref},
+		})
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeTarEntry(tw, "artifacts/index.json", data)
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// extractTarPath resolves a tar entry under dir, rejecting "../" traversal
+// (CWE-22 / zip-slip). ok is false for entries outside app/, to be skipped.
+func extractTarPath(dir string, name string) (path string, ok bool, err error) {
+	rel := strings.TrimPrefix(name, "app/")
+	if rel == name || rel == "" {
+		return "", false, nil
+	}
+	cleaned := filepath.Clean(rel)
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) || filepath.IsAbs(cleaned) {
+		return "", false, fmt.Errorf("bundle: tar entry %q escapes the bundle root", name)
+	}
+	return filepath.Join(dir, cleaned), true, nil
+}
+
+// LoadBundle reads a bundle produced by Bundle and reconstructs a chartImpl from it.
+func LoadBundle(thread *starlark.Thread, repo Repo, reader io.Reader) (*chartImpl, error) {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	dir, err := ioutil.TempDir("", "shalm-bundle-")
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		target, ok, err := extractTarPath(dir, hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if hdr.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.Close()
+	}
+	return newChart(thread, repo, dir)
+}
+
+// CNABAction maps a CNAB action name (install, uninstall, upgrade) onto the
+// chart's existing Starlark apply/delete methods.
+func (c *chartImpl) CNABAction(thread *starlark.Thread, action string, k K8s) error {
+	switch action {
+	case cnabActionInstall, cnabActionUpgrade:
+		return c.Apply(thread, k)
+	case cnabActionUninstall:
+		return c.Delete(thread, k)
+	default:
+		return fmt.Errorf("cnab: unsupported action %q", action)
+	}
+}
@@ -0,0 +1,153 @@
+package shalm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+func TestExtractTarPathRejectsTraversal(t *testing.T) {
+	cases := []struct {
+		name    string
+		wantOK  bool
+		wantErr bool
+	}{
+		{"app/templates/deployment.yaml", true, false},
+		{"bundle.json", false, false},
+		{"artifacts/index.json", false, false},
+		{"app/../../etc/passwd", false, true},
+		{"app/../secret", false, true},
+	}
+	for _, tc := range cases {
+		_, ok, err := extractTarPath("/tmp/bundle-root", tc.name)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("extractTarPath(%q): err = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+		if err == nil && ok != tc.wantOK {
+			t.Errorf("extractTarPath(%q): ok = %v, want %v", tc.name, ok, tc.wantOK)
+		}
+	}
+}
+
+func TestBundleWritesImageLayoutAndParameters(t *testing.T) {
+	c := &chartImpl{
+		dir:    t.TempDir(),
+		clazz:  chartClass{Name: "mychart"},
+		values: starlark.StringDict{"image": starlark.String("example.com/app:1.2.3")},
+	}
+
+	var buf bytes.Buffer
+	if err := c.Bundle(&buf); err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	found := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar.Next: %v", err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s: %v", hdr.Name, err)
+		}
+		found[hdr.Name] = content
+	}
+
+	if _, ok := found["artifacts/oci-layout"]; !ok {
+		t.Errorf("Bundle did not write artifacts/oci-layout")
+	}
+	indexData, ok := found["artifacts/index.json"]
+	if !ok {
+		t.Fatalf("Bundle did not write artifacts/index.json")
+	}
+	var index ociIndex
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		t.Fatalf("unmarshal index.json: %v", err)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Annotations["org.opencontainers.image.ref.name"] != "example.com/app:1.2.3" {
+		t.Errorf("artifacts/index.json missing the pinned image reference: %+v", index)
+	}
+
+	bundleData, ok := found["bundle.json"]
+	if !ok {
+		t.Fatalf("Bundle did not write bundle.json")
+	}
+	var bundle cnabBundle
+	if err := json.Unmarshal(bundleData, &bundle); err != nil {
+		t.Fatalf("unmarshal bundle.json: %v", err)
+	}
+	if _, ok := bundle.Parameters["image"]; !ok {
+		t.Errorf("bundle.json missing parameter for values key %q", "image")
+	}
+	if len(bundle.Images) != 1 {
+		t.Errorf("bundle.json missing images entry, got %v", bundle.Images)
+	}
+}
+
+func TestBundleParametersExcludeJewelsAndSubcharts(t *testing.T) {
+	sub := &chartImpl{dir: t.TempDir(), clazz: chartClass{Name: "subchart"}, values: starlark.StringDict{}}
+	c := &chartImpl{
+		dir:   t.TempDir(),
+		clazz: chartClass{Name: "mychart"},
+		values: starlark.StringDict{
+			"replicas": starlark.MakeInt(3),
+			"db":       &jewel{backend: &fakeJewelBackend{name: "db"}, name: "db"},
+			"sub":      sub,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := c.Bundle(&buf); err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var bundle cnabBundle
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar.Next: %v", err)
+		}
+		if hdr.Name != "bundle.json" {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read bundle.json: %v", err)
+		}
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			t.Fatalf("unmarshal bundle.json: %v", err)
+		}
+	}
+
+	if _, ok := bundle.Parameters["replicas"]; !ok {
+		t.Errorf("bundle.json missing parameter for values key %q", "replicas")
+	}
+	if _, ok := bundle.Parameters["db"]; ok {
+		t.Errorf("bundle.json has a parameter for jewel field %q, want it excluded", "db")
+	}
+	if _, ok := bundle.Parameters["sub"]; ok {
+		t.Errorf("bundle.json has a parameter for subchart field %q, want it excluded", "sub")
+	}
+}
@@ -1,6 +1,7 @@
 package shalm
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/blang/semver"
 	"go.starlark.net/starlark"
+
+	"cuelang.org/go/cue"
 )
 
 type chartImpl struct {
@@ -21,6 +24,9 @@ type chartImpl struct {
 	namespace string
 	suffix    string
 	initFunc  *starlark.Function
+	schema    cue.Value // compiled values.cue, the zero value if none was present
+
+	discoverySources map[string]DiscoverySource
 }
 
 var (
@@ -52,6 +58,14 @@ func newChart(thread *starlark.Thread, repo Repo, dir string, opts ...ChartOptio
 	} else {
 		hasChartYaml = true
 	}
+	if err := c.loadValuesCue(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := c.validateValues(); err != nil {
+		return nil, err
+	}
 	if err := c.init(thread, repo, hasChartYaml, co.args, co.kwargs); err != nil {
 		return nil, err
 	}
@@ -187,7 +201,18 @@ func (c *chartImpl) AttrNames() []string {
 
 // SetField -
 func (c *chartImpl) SetField(name string, val starlark.Value) error {
-	c.values[name] = unwrapDict(val)
+	v := unwrapDict(val)
+	if j, ok := v.(*jewel); ok {
+		j.chart = c.GetName()
+	}
+	if d, ok := v.(*discovery); ok {
+		if c.discoverySources == nil {
+			c.discoverySources = map[string]DiscoverySource{}
+		}
+		c.discoverySources[name] = d.source
+		v = starlark.None
+	}
+	c.values[name] = v
 	return nil
 }
 
@@ -216,9 +241,9 @@ func (c *chartImpl) apply(thread *starlark.Thread, k K8sValue) error {
 		return err
 	})
 	if err != nil {
-		return err
+		return newError(thread, c.GetName(), "apply", err)
 	}
-	return c.applyLocal(thread, k, &K8sOptions{}, "")
+	return newError(thread, c.GetName(), "apply", c.applyLocal(thread, k, &K8sOptions{}, ""))
 }
 
 func (c *chartImpl) applyLocalFunction() starlark.Callable {
@@ -239,10 +264,13 @@ func (c *chartImpl) applyLocal(thread *starlark.Thread, k K8sValue, k8sOptions *
 		return v.read(k)
 	})
 	if err != nil {
-		return err
+		return newError(thread, c.GetName(), "applyLocal", err)
+	}
+	if err := c.resolveDiscovery(context.Background()); err != nil {
+		return newError(thread, c.GetName(), "applyLocal", err)
 	}
 	k8sOptions.Namespaced = false
-	return k.Apply(decode(c.template(thread, glob, true)), k8sOptions)
+	return newError(thread, c.GetName(), "applyLocal", k.Apply(decode(c.template(thread, glob, true)), k8sOptions))
 }
 
 func (c *chartImpl) Delete(thread *starlark.Thread, k K8s) error {
@@ -270,9 +298,9 @@ func (c *chartImpl) delete(thread *starlark.Thread, k K8sValue) error {
 		return err
 	})
 	if err != nil {
-		return err
+		return newError(thread, c.GetName(), "delete", err)
 	}
-	return c.deleteLocal(thread, k, &K8sOptions{}, "")
+	return newError(thread, c.GetName(), "delete", c.deleteLocal(thread, k, &K8sOptions{}, ""))
 }
 
 func (c *chartImpl) deleteLocalFunction() starlark.Callable {
@@ -292,11 +320,11 @@ func (c *chartImpl) deleteLocal(thread *starlark.Thread, k K8sValue, k8sOptions
 	k8sOptions.Namespaced = false
 	err := k.Delete(decode(c.template(thread, glob, false)), k8sOptions)
 	if err != nil {
-		return err
+		return newError(thread, c.GetName(), "deleteLocal", err)
 	}
-	return c.eachVault(func(v *vault) error {
+	return newError(thread, c.GetName(), "deleteLocal", c.eachVault(func(v *vault) error {
 		return v.delete()
-	})
+	}))
 }
 
 func (c *chartImpl) eachSubChart(block func(subChart *chartImpl) error) error {
@@ -325,8 +353,12 @@ func (c *chartImpl) eachVault(block func(x *vault) error) error {
 	return nil
 }
 
-func (c *chartImpl) mergeValues(values map[string]interface{}) {
+// mergeValues merges values (e.g. a subchart override) into c.values and
+// re-validates against values.cue. Its caller, which applies such overrides
+// while loading a chart's subcharts, is outside this tree's snapshot.
+func (c *chartImpl) mergeValues(values map[string]interface{}) error {
 	for k, v := range values {
 		c.values[k] = merge(c.values[k], toStarlark(v))
 	}
-}
\ No newline at end of file
+	return c.validateValues()
+}
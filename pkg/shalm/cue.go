@@ -0,0 +1,180 @@
+package shalm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"go.starlark.net/starlark"
+)
+
+func init() {
+	registerBuiltin("validate", validateBuiltin)
+}
+
+// ValidationError reports every offending path from a CUE schema unification.
+type ValidationError struct {
+	Paths []string
+}
+
+// Error -
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed:\n\t%s", strings.Join(e.Paths, "\n\t"))
+}
+
+// loadValuesCue compiles values.cue, if present, and keeps the result on
+// c.schema so validateValues can unify it against the merged values.
+func (c *chartImpl) loadValuesCue() error {
+	data, err := ioutil.ReadFile(c.path("values.cue"))
+	if err != nil {
+		return err
+	}
+	v := cuecontext.New().CompileBytes(data, cue.Filename("values.cue"))
+	if err := v.Err(); err != nil {
+		return err
+	}
+	c.schema = v
+	return nil
+}
+
+// validateValues unifies the plain-value subset of c.values against c.schema,
+// if one was loaded, skipping jewels/subcharts/discovery placeholders.
+func (c *chartImpl) validateValues() error {
+	if !c.schema.Exists() {
+		return nil
+	}
+	plain := plainValues(c.values)
+	for name := range c.discoverySources {
+		delete(plain, name)
+	}
+	goValues, err := starlarkDictToGo(plain)
+	if err != nil {
+		return err
+	}
+	return validate(c.schema.Context(), c.schema, goValues)
+}
+
+// plainValues returns the subset of dict holding ordinary data, excluding
+// jewels, subcharts and discovery placeholders.
+func plainValues(dict starlark.StringDict) starlark.StringDict {
+	result := make(starlark.StringDict, len(dict))
+	for k, v := range dict {
+		switch v.(type) {
+		case *jewel, *chartImpl, *discovery:
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// validate unifies value against schema, returning a ValidationError if it
+// does not satisfy it.
+func validate(ctx *cue.Context, schema cue.Value, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	unified := schema.Unify(ctx.CompileBytes(data))
+	if err := unified.Validate(cue.Concrete(true)); err != nil {
+		errs := cueerrors.Errors(err)
+		paths := make([]string, 0, len(errs))
+		for _, e := range errs {
+			paths = append(paths, fmt.Sprintf("%s: %s", pathString(e.Path()), e.Error()))
+		}
+		return &ValidationError{Paths: paths}
+	}
+	return nil
+}
+
+func pathString(path []string) string {
+	if len(path) == 0 {
+		return "(root)"
+	}
+	return strings.Join(path, ".")
+}
+
+// validateBuiltin is the `validate(schema=..., value=...)` Starlark builtin.
+func validateBuiltin(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var schemaSrc string
+	var value starlark.Value
+	if err := starlark.UnpackArgs("validate", args, kwargs, "schema", &schemaSrc, "value", &value); err != nil {
+		return nil, err
+	}
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(schemaSrc)
+	if err := schema.Err(); err != nil {
+		return nil, err
+	}
+	goValue, err := starlarkValueToGo(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(ctx, schema, goValue); err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
+
+func starlarkDictToGo(dict starlark.StringDict) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(dict))
+	for k, v := range dict {
+		goValue, err := starlarkValueToGo(v)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = goValue
+	}
+	return result, nil
+}
+
+// starlarkValueToGo converts a starlark.Value into the plain Go value
+// encoding/json and cue expect.
+func starlarkValueToGo(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("validate: integer %s out of range", v)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.List:
+		result := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := starlarkValueToGo(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, elem)
+		}
+		return result, nil
+	case *starlark.Dict:
+		result := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("validate: dict keys must be strings, got %s", item[0].Type())
+			}
+			elem, err := starlarkValueToGo(item[1])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = elem
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("validate: unsupported value of type %s", v.Type())
+	}
+}
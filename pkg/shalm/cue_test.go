@@ -0,0 +1,81 @@
+package shalm
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"go.starlark.net/starlark"
+)
+
+func TestValidatePass(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`{name: string, replicas: int}`)
+	if err := schema.Err(); err != nil {
+		t.Fatalf("compile schema: %v", err)
+	}
+	err := validate(ctx, schema, map[string]interface{}{"name": "db", "replicas": 3})
+	if err != nil {
+		t.Errorf("validate() = %v, want nil", err)
+	}
+}
+
+func TestValidateReportsEveryOffendingPath(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`{name: string, replicas: int}`)
+	if err := schema.Err(); err != nil {
+		t.Fatalf("compile schema: %v", err)
+	}
+	err := validate(ctx, schema, map[string]interface{}{"name": 1, "replicas": "three"})
+	if err == nil {
+		t.Fatalf("validate() = nil, want an error")
+	}
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("validate() error type = %T, want *ValidationError", err)
+	}
+	if len(ve.Paths) != 2 {
+		t.Fatalf("ValidationError.Paths = %v, want 2 entries (one per offending field)", ve.Paths)
+	}
+	joined := strings.Join(ve.Paths, "\n")
+	if !strings.Contains(joined, "name") || !strings.Contains(joined, "replicas") {
+		t.Errorf("ValidationError.Paths = %v, want entries mentioning both name and replicas", ve.Paths)
+	}
+}
+
+func TestPlainValuesSkipsOpaqueTypes(t *testing.T) {
+	dict := starlark.StringDict{
+		"plain": starlark.String("ok"),
+		"jewel": &jewel{name: "db"},
+		"sub":   &chartImpl{},
+		"disc":  &discovery{name: "svc"},
+	}
+	result := plainValues(dict)
+	if len(result) != 1 {
+		t.Fatalf("plainValues() = %v, want only the plain entry", result)
+	}
+	if _, ok := result["plain"]; !ok {
+		t.Errorf("plainValues() dropped the plain entry")
+	}
+}
+
+// A chart combining values.cue with a declared jewel or subchart field must
+// not fail validation just because those fields aren't plain data.
+func TestValidateValuesIgnoresJewelsAndSubcharts(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`{name: string}`)
+	if err := schema.Err(); err != nil {
+		t.Fatalf("compile schema: %v", err)
+	}
+	c := &chartImpl{
+		schema: schema,
+		values: starlark.StringDict{
+			"name":  starlark.String("db"),
+			"user":  &jewel{name: "user"},
+			"redis": &chartImpl{},
+		},
+	}
+	if err := c.validateValues(); err != nil {
+		t.Errorf("validateValues() = %v, want nil", err)
+	}
+}
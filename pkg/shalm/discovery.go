@@ -0,0 +1,192 @@
+package shalm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	consulapi "github.com/hashicorp/consul/api"
+	clientv3 "go.etcd.io/etcd/clientv3"
+	"go.starlark.net/starlark"
+)
+
+func init() {
+	registerBuiltin("consul_service", consulService)
+	registerBuiltin("etcd_key", etcdKey)
+}
+
+// DiscoverySource is a lazily-resolved value backed by live infrastructure state.
+type DiscoverySource interface {
+	Resolve(ctx context.Context) (starlark.Value, error)
+	Watch(ctx context.Context, cb func()) error
+}
+
+// discovery is the placeholder Starlark value produced by consul_service/etcd_key.
+type discovery struct {
+	source DiscoverySource
+	name   string
+}
+
+var (
+	_ starlark.Value = (*discovery)(nil)
+)
+
+// String -
+func (d *discovery) String() string { return fmt.Sprintf("discovery(%s)", d.name) }
+
+// Type -
+func (d *discovery) Type() string { return "discovery" }
+
+// Freeze -
+func (d *discovery) Freeze() {}
+
+// Truth -
+func (d *discovery) Truth() starlark.Bool { return true }
+
+// Hash -
+func (d *discovery) Hash() (uint32, error) { return starlark.String(d.name).Hash() }
+
+// resolveDiscovery resolves every DiscoverySource declared on the chart into c.values.
+func (c *chartImpl) resolveDiscovery(ctx context.Context) error {
+	for name, source := range c.discoverySources {
+		value, err := source.Resolve(ctx)
+		if err != nil {
+			return fmt.Errorf("discovery %q: %w", name, err)
+		}
+		c.values[name] = value
+	}
+	return nil
+}
+
+// watchDiscovery registers cb with every DiscoverySource declared on the chart.
+func (c *chartImpl) watchDiscovery(ctx context.Context, cb func()) error {
+	for _, source := range c.discoverySources {
+		if err := source.Watch(ctx, cb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type consulSource struct {
+	client  *consulapi.Client
+	service string
+	tag     string
+}
+
+func newConsulSource(service string, tag string) (*consulSource, error) {
+	client, err := consulapi.NewClient(consulapi.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &consulSource{client: client, service: service, tag: tag}, nil
+}
+
+// Resolve returns "host:port" of a healthy instance of the service.
+func (s *consulSource) Resolve(ctx context.Context) (starlark.Value, error) {
+	entries, _, err := s.client.Health().Service(s.service, s.tag, true, (&consulapi.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no healthy instances of service %q", s.service)
+	}
+	entry := entries[0]
+	addr := entry.Service.Address
+	if addr == "" {
+		addr = entry.Node.Address
+	}
+	return starlark.String(fmt.Sprintf("%s:%d", addr, entry.Service.Port)), nil
+}
+
+// Watch long-polls Consul's blocking query API and invokes cb on change.
+func (s *consulSource) Watch(ctx context.Context, cb func()) error {
+	go func() {
+		var lastIndex uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			_, meta, err := s.client.Health().Service(s.service, s.tag, true, (&consulapi.QueryOptions{WaitIndex: lastIndex}).WithContext(ctx))
+			if err != nil {
+				return
+			}
+			if meta.LastIndex != lastIndex {
+				changed := lastIndex != 0
+				lastIndex = meta.LastIndex
+				if changed {
+					cb()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// consulService is the `consul_service(service, tag="")` Starlark builtin.
+func consulService(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var service, tag string
+	if err := starlark.UnpackArgs("consul_service", args, kwargs, "service", &service, "tag?", &tag); err != nil {
+		return nil, err
+	}
+	source, err := newConsulSource(service, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &discovery{source: source, name: service}, nil
+}
+
+type etcdSource struct {
+	client *clientv3.Client
+	key    string
+}
+
+func newEtcdSource(key string) (*etcdSource, error) {
+	raw := os.Getenv("ETCD_ENDPOINTS")
+	if raw == "" {
+		return nil, fmt.Errorf("etcd_key: ETCD_ENDPOINTS is not set")
+	}
+	client, err := clientv3.New(clientv3.Config{Endpoints: strings.Split(raw, ",")})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdSource{client: client, key: key}, nil
+}
+
+// Resolve returns the current value stored at the etcd key.
+func (s *etcdSource) Resolve(ctx context.Context) (starlark.Value, error) {
+	resp, err := s.client.Get(ctx, s.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("etcd key %q not found", s.key)
+	}
+	return starlark.String(string(resp.Kvs[0].Value)), nil
+}
+
+// Watch subscribes to the etcd key and invokes cb on every update.
+func (s *etcdSource) Watch(ctx context.Context, cb func()) error {
+	go func() {
+		for range s.client.Watch(ctx, s.key) {
+			cb()
+		}
+	}()
+	return nil
+}
+
+// etcdKey is the `etcd_key(key)` Starlark builtin.
+func etcdKey(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	if err := starlark.UnpackArgs("etcd_key", args, kwargs, "key", &key); err != nil {
+		return nil, err
+	}
+	source, err := newEtcdSource(key)
+	if err != nil {
+		return nil, err
+	}
+	return &discovery{source: source, name: key}, nil
+}
@@ -0,0 +1,87 @@
+package shalm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.starlark.net/starlark"
+)
+
+type fakeDiscoverySource struct {
+	value      starlark.Value
+	resolveErr error
+	watched    bool
+}
+
+func (f *fakeDiscoverySource) Resolve(ctx context.Context) (starlark.Value, error) {
+	return f.value, f.resolveErr
+}
+
+func (f *fakeDiscoverySource) Watch(ctx context.Context, cb func()) error {
+	f.watched = true
+	return nil
+}
+
+func TestResolveDiscoveryReplacesValue(t *testing.T) {
+	source := &fakeDiscoverySource{value: starlark.String("10.0.0.1:5432")}
+	c := &chartImpl{
+		values:           starlark.StringDict{"db": starlark.None},
+		discoverySources: map[string]DiscoverySource{"db": source},
+	}
+	if err := c.resolveDiscovery(context.Background()); err != nil {
+		t.Fatalf("resolveDiscovery: %v", err)
+	}
+	got, ok := c.values["db"].(starlark.String)
+	if !ok || string(got) != "10.0.0.1:5432" {
+		t.Errorf("c.values[\"db\"] = %v, want the resolved discovery value", c.values["db"])
+	}
+}
+
+func TestResolveDiscoveryPropagatesNamedError(t *testing.T) {
+	source := &fakeDiscoverySource{resolveErr: errors.New("no healthy instances")}
+	c := &chartImpl{
+		values:           starlark.StringDict{"db": starlark.None},
+		discoverySources: map[string]DiscoverySource{"db": source},
+	}
+	err := c.resolveDiscovery(context.Background())
+	if err == nil {
+		t.Fatal("resolveDiscovery() = nil, want an error")
+	}
+	if got := err.Error(); got != `discovery "db": no healthy instances` {
+		t.Errorf("resolveDiscovery() error = %q, want it to name the discovery field", got)
+	}
+}
+
+func TestWatchDiscoveryRegistersEverySource(t *testing.T) {
+	a := &fakeDiscoverySource{}
+	b := &fakeDiscoverySource{}
+	c := &chartImpl{discoverySources: map[string]DiscoverySource{"a": a, "b": b}}
+	if err := c.watchDiscovery(context.Background(), func() {}); err != nil {
+		t.Fatalf("watchDiscovery: %v", err)
+	}
+	if !a.watched || !b.watched {
+		t.Errorf("watchDiscovery() did not register every DiscoverySource: a=%v b=%v", a.watched, b.watched)
+	}
+}
+
+func TestSetFieldMovesDiscoveryIntoSourcesMap(t *testing.T) {
+	c := &chartImpl{values: starlark.StringDict{}, clazz: chartClass{Name: "mychart"}}
+	source := &fakeDiscoverySource{value: starlark.String("v")}
+	if err := c.SetField("db", &discovery{source: source, name: "db"}); err != nil {
+		t.Fatalf("SetField: %v", err)
+	}
+	if c.discoverySources["db"] != source {
+		t.Errorf("SetField() did not register the discovery source under %q", "db")
+	}
+	if c.values["db"] != starlark.None {
+		t.Errorf("SetField() left a *discovery placeholder in c.values instead of starlark.None")
+	}
+}
+
+func TestNewEtcdSourceRequiresEndpoints(t *testing.T) {
+	t.Setenv("ETCD_ENDPOINTS", "")
+	if _, err := newEtcdSource("foo"); err == nil {
+		t.Fatal("newEtcdSource() = nil error, want one naming ETCD_ENDPOINTS")
+	}
+}
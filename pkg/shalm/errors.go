@@ -0,0 +1,100 @@
+package shalm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// Debug controls whether FormatError renders the full Starlark call frame
+// alongside the compact error message. It is wired to the CLI's --debug flag.
+var Debug bool
+
+// Error wraps an error encountered while executing a chart or a jewel backend
+// with the context needed to locate which Starlark line failed.
+type Error struct {
+	Chart string
+	Jewel string
+	Op    string
+	Frame string
+	Cause error
+}
+
+// Error -
+func (e *Error) Error() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "chart %q", e.Chart)
+	if e.Jewel != "" {
+		fmt.Fprintf(buf, ": jewel %q", e.Jewel)
+	}
+	if e.Op != "" {
+		fmt.Fprintf(buf, ": %s", e.Op)
+	}
+	fmt.Fprintf(buf, ": %v", e.Cause)
+	return buf.String()
+}
+
+// Unwrap -
+func (e *Error) Unwrap() error { return e.Cause }
+
+// StackTrace renders the compact message followed by the Starlark call frame
+// that was active when the error occurred, for --debug output.
+func (e *Error) StackTrace() string {
+	if e.Frame == "" {
+		return e.Error()
+	}
+	return fmt.Sprintf("%s\n\tat %s", e.Error(), e.Frame)
+}
+
+// newError wraps err, encountered while performing op against chart, with the
+// Starlark call frame active on thread. err is returned unchanged if it is
+// nil or already a *Error, so wrapping happens once at the innermost site.
+func newError(thread *starlark.Thread, chart string, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{Chart: chart, Op: op, Frame: callFrame(thread), Cause: err}
+}
+
+// newJewelError is like newError but also records the name of the jewel the
+// error occurred against.
+func newJewelError(thread *starlark.Thread, chart string, jewel string, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+	return &Error{Chart: chart, Jewel: jewel, Op: op, Frame: callFrame(thread), Cause: err}
+}
+
+func callFrame(thread *starlark.Thread) string {
+	if thread == nil {
+		return ""
+	}
+	stack := thread.CallStack()
+	if len(stack) == 0 {
+		return ""
+	}
+	top := stack.At(0)
+	return fmt.Sprintf("%s:%d", top.Pos.Filename(), top.Pos.Line)
+}
+
+// FormatError renders err for display on the CLI: the compact
+// `chart "foo": jewel "db": vault read: <cause>` message, or the full
+// Starlark call frame when Debug is set.
+func FormatError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var se *Error
+	if Debug && errors.As(err, &se) {
+		return se.StackTrace()
+	}
+	return err.Error()
+}
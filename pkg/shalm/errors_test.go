@@ -0,0 +1,67 @@
+package shalm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorFormatsCompactMessage(t *testing.T) {
+	err := &Error{Chart: "foo", Jewel: "db", Op: "vault read", Cause: errors.New("connection refused")}
+	want := `chart "foo": jewel "db": vault read: connection refused`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorStackTraceIncludesFrame(t *testing.T) {
+	err := &Error{Chart: "foo", Op: "apply", Frame: "Chart.star:12", Cause: errors.New("boom")}
+	got := err.StackTrace()
+	if !strings.Contains(got, err.Error()) || !strings.Contains(got, "Chart.star:12") {
+		t.Errorf("StackTrace() = %q, want it to contain both the compact message and the frame", got)
+	}
+}
+
+func TestErrorStackTraceWithoutFrameFallsBackToError(t *testing.T) {
+	err := &Error{Chart: "foo", Op: "apply", Cause: errors.New("boom")}
+	if got, want := err.StackTrace(), err.Error(); got != want {
+		t.Errorf("StackTrace() = %q, want %q", got, want)
+	}
+}
+
+func TestNewErrorDoesNotDoubleWrap(t *testing.T) {
+	inner := newError(nil, "foo", "applyLocal", errors.New("jewel \"db\": vault read: connection refused"))
+	outer := newError(nil, "foo", "apply", inner)
+	if outer != inner {
+		t.Fatalf("newError() re-wrapped an already-wrapped *Error: got %v", outer)
+	}
+	want := `chart "foo": applyLocal: jewel "db": vault read: connection refused`
+	if got := outer.Error(); got != want {
+		t.Errorf("Error() = %q, want %q (chart name must appear exactly once)", got, want)
+	}
+}
+
+func TestNewJewelErrorDoesNotDoubleWrap(t *testing.T) {
+	inner := newJewelError(nil, "foo", "db", "vault read", errors.New("connection refused"))
+	outer := newJewelError(nil, "foo", "db", "apply", inner)
+	if outer != inner {
+		t.Fatalf("newJewelError() re-wrapped an already-wrapped *Error: got %v", outer)
+	}
+}
+
+func TestFormatErrorCompactByDefault(t *testing.T) {
+	Debug = false
+	err := &Error{Chart: "foo", Op: "apply", Frame: "Chart.star:12", Cause: errors.New("boom")}
+	if got := FormatError(err); strings.Contains(got, "Chart.star:12") {
+		t.Errorf("FormatError() = %q, want the frame omitted when Debug is false", got)
+	}
+}
+
+func TestFormatErrorIncludesFrameWhenDebug(t *testing.T) {
+	Debug = true
+	defer func() { Debug = false }()
+	err := &Error{Chart: "foo", Op: "apply", Frame: "Chart.star:12", Cause: errors.New("boom")}
+	if got := FormatError(err); !strings.Contains(got, "Chart.star:12") {
+		t.Errorf("FormatError() = %q, want it to contain the frame when Debug is true", got)
+	}
+}
@@ -79,6 +79,8 @@ type jewel struct {
 	backend JewelBackend
 	state   int
 	name    string
+	chart   string
+	thread  *starlark.Thread
 	data    map[string][]byte
 }
 
@@ -87,10 +89,11 @@ var (
 )
 
 // NewJewel -
-func NewJewel(backend JewelBackend, name string) (starlark.Value, error) {
+func NewJewel(thread *starlark.Thread, backend JewelBackend, name string) (starlark.Value, error) {
 	return &jewel{
 		backend: backend,
 		name:    name,
+		thread:  thread,
 		data:    map[string][]byte{},
 	}, nil
 }
@@ -118,7 +121,7 @@ func (c *jewel) read(v Vault) error {
 	data, err := v.Read(c.name)
 	if err != nil {
 		if !v.IsNotExist(err) {
-			return err
+			return newJewelError(c.thread, c.chart, c.name, "vault read", err)
 		}
 	} else {
 		c.data = data
@@ -128,7 +131,7 @@ func (c *jewel) read(v Vault) error {
 }
 
 func (c *jewel) write(v Vault) error {
-	return v.Write(c.name, c.data)
+	return newJewelError(c.thread, c.chart, c.name, "vault write", v.Write(c.name, c.data))
 }
 
 func (c *jewel) ensure() (err error) {
@@ -137,7 +140,7 @@ func (c *jewel) ensure() (err error) {
 	case stateLoaded:
 		data, err = c.backend.Apply(c.data)
 		if err != nil {
-			return
+			return newJewelError(c.thread, c.chart, c.name, "apply", err)
 		}
 	case stateInit:
 		complex, ok := c.backend.(ComplexJewelBackend)
@@ -147,7 +150,7 @@ func (c *jewel) ensure() (err error) {
 			data, err = c.backend.Apply(make(map[string][]byte))
 		}
 		if err != nil {
-			return
+			return newJewelError(c.thread, c.chart, c.name, "template", err)
 		}
 	case stateReady:
 		return nil
@@ -0,0 +1,86 @@
+package shalm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"go.starlark.net/starlark"
+)
+
+func init() {
+	RegisterJewelBackend("awssm", newAWSSecretsManagerBackend)
+	registerBuiltin("awssm_jewel", awssmJewel)
+}
+
+// awsSecretsManagerBackend is a read-only JewelBackend backed by AWS Secrets Manager.
+type awsSecretsManagerBackend struct {
+	client   *secretsmanager.SecretsManager
+	secretID string
+	keys     map[string]string
+}
+
+func newAWSSecretsManagerBackend(u *url.URL, keys map[string]string) (JewelBackend, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, fmt.Errorf("awssm jewel %q: %w", u, err)
+	}
+	secretID := strings.TrimPrefix(u.Path, "/")
+	if u.Host != "" {
+		secretID = u.Host + "/" + secretID
+	}
+	return &awsSecretsManagerBackend{client: secretsmanager.New(sess), secretID: secretID, keys: keys}, nil
+}
+
+// Name -
+func (a *awsSecretsManagerBackend) Name() string { return "awssm_jewel" }
+
+// Keys -
+func (a *awsSecretsManagerBackend) Keys() map[string]string { return a.keys }
+
+// Apply re-fetches the secret from AWS Secrets Manager.
+func (a *awsSecretsManagerBackend) Apply(map[string][]byte) (map[string][]byte, error) {
+	return a.Template()
+}
+
+// Template fetches and JSON-decodes the secret value.
+func (a *awsSecretsManagerBackend) Template() (map[string][]byte, error) {
+	out, err := a.client.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String(a.secretID)})
+	if err != nil {
+		return nil, fmt.Errorf("awssm read %q: %w", a.secretID, err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(aws.StringValue(out.SecretString)), &values); err != nil {
+		return nil, fmt.Errorf("awssm read %q: %w", a.secretID, err)
+	}
+	result := make(map[string][]byte, len(values))
+	for k, v := range values {
+		result[k] = []byte(v)
+	}
+	return result, nil
+}
+
+// Delete is a no-op: shalm does not own externally materialized secrets.
+func (a *awsSecretsManagerBackend) Delete() error { return nil }
+
+// awssmJewel is the `awssm_jewel(secret_id, keys=[...])` Starlark builtin.
+func awssmJewel(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var secretID string
+	var keyList *starlark.List
+	if err := starlark.UnpackArgs("awssm_jewel", args, kwargs, "secret_id", &secretID, "keys", &keyList); err != nil {
+		return nil, err
+	}
+	keys, err := jewelKeysFromList(keyList)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := ResolveJewelBackend("awssm://"+secretID, keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewJewel(thread, backend, secretID)
+}
@@ -0,0 +1,21 @@
+package shalm
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNewAWSSecretsManagerBackendJoinsHostAndPath(t *testing.T) {
+	u, err := url.Parse("awssm://myapp/db-pass")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	backend, err := newAWSSecretsManagerBackend(u, nil)
+	if err != nil {
+		t.Fatalf("newAWSSecretsManagerBackend: %v", err)
+	}
+	got := backend.(*awsSecretsManagerBackend).secretID
+	if got != "myapp/db-pass" {
+		t.Errorf("secretID = %q, want %q", got, "myapp/db-pass")
+	}
+}
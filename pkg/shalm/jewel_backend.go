@@ -0,0 +1,35 @@
+package shalm
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// JewelBackendFactory creates a JewelBackend for a parsed jewel URI, given the
+// set of template keys the chart declared interest in.
+type JewelBackendFactory func(u *url.URL, keys map[string]string) (JewelBackend, error)
+
+var jewelBackendRegistry = map[string]JewelBackendFactory{}
+
+// RegisterJewelBackend registers a JewelBackendFactory under a URI scheme, e.g.
+// "vault", "awssm" or "gsm". It is meant to be called from init() by backend
+// implementations so that ResolveJewelBackend can dispatch on the scheme of a
+// jewel URI such as vault://path/to/secret.
+func RegisterJewelBackend(scheme string, factory JewelBackendFactory) {
+	jewelBackendRegistry[scheme] = factory
+}
+
+// ResolveJewelBackend looks up the JewelBackendFactory registered for the
+// scheme of uri and uses it to build a JewelBackend. It returns an error if
+// uri cannot be parsed or no backend is registered for its scheme.
+func ResolveJewelBackend(uri string, keys map[string]string) (JewelBackend, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("jewel backend %q: %w", uri, err)
+	}
+	factory, ok := jewelBackendRegistry[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("jewel backend %q: no backend registered for scheme %q", uri, u.Scheme)
+	}
+	return factory(u, keys)
+}
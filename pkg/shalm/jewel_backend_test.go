@@ -0,0 +1,42 @@
+package shalm
+
+import (
+	"net/url"
+	"testing"
+)
+
+type fakeJewelBackend struct{ name string }
+
+func (f *fakeJewelBackend) Name() string            { return f.name }
+func (f *fakeJewelBackend) Keys() map[string]string { return nil }
+func (f *fakeJewelBackend) Apply(map[string][]byte) (map[string][]byte, error) {
+	return nil, nil
+}
+
+func TestResolveJewelBackendDispatchesOnScheme(t *testing.T) {
+	RegisterJewelBackend("fake-test-scheme", func(u *url.URL, keys map[string]string) (JewelBackend, error) {
+		return &fakeJewelBackend{name: u.Path}, nil
+	})
+
+	backend, err := ResolveJewelBackend("fake-test-scheme:///secret/pg", map[string]string{"username": "username"})
+	if err != nil {
+		t.Fatalf("ResolveJewelBackend: %v", err)
+	}
+	if backend.Name() != "/secret/pg" {
+		t.Errorf("backend.Name() = %q, want %q", backend.Name(), "/secret/pg")
+	}
+}
+
+func TestResolveJewelBackendUnknownScheme(t *testing.T) {
+	_, err := ResolveJewelBackend("no-such-scheme://x", nil)
+	if err == nil {
+		t.Fatal("ResolveJewelBackend() = nil error, want an error for an unregistered scheme")
+	}
+}
+
+func TestResolveJewelBackendInvalidURI(t *testing.T) {
+	_, err := ResolveJewelBackend("://not-a-uri", nil)
+	if err == nil {
+		t.Fatal("ResolveJewelBackend() = nil error, want an error for an unparsable URI")
+	}
+}
@@ -0,0 +1,90 @@
+package shalm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"go.starlark.net/starlark"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+)
+
+func init() {
+	RegisterJewelBackend("gsm", newGCPSecretManagerBackend)
+	registerBuiltin("gsm_jewel", gsmJewel)
+}
+
+// gcpSecretManagerBackend is a read-only JewelBackend backed by GCP Secret
+// Manager; name is the fully qualified resource name derived from a gsm://... URI.
+type gcpSecretManagerBackend struct {
+	client *secretmanager.Client
+	name   string
+	keys   map[string]string
+}
+
+func newGCPSecretManagerBackend(u *url.URL, keys map[string]string) (JewelBackend, error) {
+	client, err := secretmanager.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("gsm jewel %q: %w", u, err)
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if u.Host != "" {
+		name = u.Host + "/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	return &gcpSecretManagerBackend{client: client, name: name, keys: keys}, nil
+}
+
+// Name -
+func (g *gcpSecretManagerBackend) Name() string { return "gsm_jewel" }
+
+// Keys -
+func (g *gcpSecretManagerBackend) Keys() map[string]string { return g.keys }
+
+// Apply re-fetches the secret from GCP Secret Manager.
+func (g *gcpSecretManagerBackend) Apply(map[string][]byte) (map[string][]byte, error) {
+	return g.Template()
+}
+
+// Template fetches and JSON-decodes the secret payload.
+func (g *gcpSecretManagerBackend) Template() (map[string][]byte, error) {
+	resp, err := g.client.AccessSecretVersion(context.Background(), &secretmanagerpb.AccessSecretVersionRequest{Name: g.name})
+	if err != nil {
+		return nil, fmt.Errorf("gsm read %q: %w", g.name, err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(resp.Payload.Data, &values); err != nil {
+		return nil, fmt.Errorf("gsm read %q: %w", g.name, err)
+	}
+	result := make(map[string][]byte, len(values))
+	for k, v := range values {
+		result[k] = []byte(v)
+	}
+	return result, nil
+}
+
+// Delete is a no-op: shalm does not own externally materialized secrets.
+func (g *gcpSecretManagerBackend) Delete() error { return nil }
+
+// gsmJewel is the `gsm_jewel(name, keys=[...])` Starlark builtin.
+func gsmJewel(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var keyList *starlark.List
+	if err := starlark.UnpackArgs("gsm_jewel", args, kwargs, "name", &name, "keys", &keyList); err != nil {
+		return nil, err
+	}
+	keys, err := jewelKeysFromList(keyList)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := ResolveJewelBackend("gsm://"+name, keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewJewel(thread, backend, name)
+}
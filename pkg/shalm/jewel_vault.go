@@ -0,0 +1,134 @@
+package shalm
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"go.starlark.net/starlark"
+)
+
+func init() {
+	RegisterJewelBackend("vault", newVaultKVBackend)
+	registerBuiltin("vault_jewel", vaultJewel)
+}
+
+// vaultKVBackend is a read-only JewelBackend backed by a HashiCorp Vault KV
+// (v1 or v2) secret engine.
+type vaultKVBackend struct {
+	client *vaultapi.Client
+	path   string
+	keys   map[string]string
+}
+
+// newVaultKVBackend builds a vaultKVBackend for a vault://path/to/secret URI,
+// authenticating via VAULT_ADDR/VAULT_TOKEN or AppRole.
+func newVaultKVBackend(u *url.URL, keys map[string]string) (JewelBackend, error) {
+	config := vaultapi.DefaultConfig()
+	if err := config.ReadEnvironment(); err != nil {
+		return nil, fmt.Errorf("vault jewel %q: %w", u, err)
+	}
+	client, err := vaultapi.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("vault jewel %q: %w", u, err)
+	}
+	if roleID, secretID := os.Getenv("VAULT_ROLE_ID"), os.Getenv("VAULT_SECRET_ID"); roleID != "" && secretID != "" {
+		if err := approleLogin(client, roleID, secretID); err != nil {
+			return nil, fmt.Errorf("vault jewel %q: %w", u, err)
+		}
+	}
+	path := strings.TrimPrefix(u.Path, "/")
+	if u.Host != "" {
+		path = u.Host + "/" + path
+	}
+	return &vaultKVBackend{client: client, path: path, keys: keys}, nil
+}
+
+func approleLogin(client *vaultapi.Client, roleID string, secretID string) error {
+	secret, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Auth == nil {
+		return fmt.Errorf("approle login returned no token")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+// Name -
+func (v *vaultKVBackend) Name() string { return "vault_jewel" }
+
+// Keys -
+func (v *vaultKVBackend) Keys() map[string]string { return v.keys }
+
+// Apply re-reads the secret from Vault, ignoring the previously stored data.
+func (v *vaultKVBackend) Apply(map[string][]byte) (map[string][]byte, error) {
+	return v.Template()
+}
+
+// Template reads the secret, unwrapping the KV v2 "data" envelope if present.
+func (v *vaultKVBackend) Template() (map[string][]byte, error) {
+	secret, err := v.client.Logical().Read(v.path)
+	if err != nil {
+		return nil, fmt.Errorf("vault read %q: %w", v.path, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("vault read %q: no secret found", v.path)
+	}
+	raw := secret.Data
+	if inner, ok := raw["data"].(map[string]interface{}); ok {
+		raw = inner
+	}
+	result := make(map[string][]byte, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			result[k] = []byte(s)
+		}
+	}
+	return result, nil
+}
+
+// Delete is a no-op: shalm does not own externally materialized secrets.
+func (v *vaultKVBackend) Delete() error { return nil }
+
+// vaultJewel is the `vault_jewel(path, keys=[...])` Starlark builtin.
+func vaultJewel(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	var keyList *starlark.List
+	if err := starlark.UnpackArgs("vault_jewel", args, kwargs, "path", &path, "keys", &keyList); err != nil {
+		return nil, err
+	}
+	keys, err := jewelKeysFromList(keyList)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := ResolveJewelBackend("vault://"+path, keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewJewel(thread, backend, path)
+}
+
+func jewelKeysFromList(list *starlark.List) (map[string]string, error) {
+	result := map[string]string{}
+	if list == nil {
+		return result, nil
+	}
+	iter := list.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for iter.Next(&item) {
+		s, ok := starlark.AsString(item)
+		if !ok {
+			return nil, fmt.Errorf("vault_jewel: keys must be strings, got %s", item.Type())
+		}
+		result[s] = s
+	}
+	return result, nil
+}
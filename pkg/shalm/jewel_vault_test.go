@@ -0,0 +1,21 @@
+package shalm
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNewVaultKVBackendJoinsHostAndPath(t *testing.T) {
+	u, err := url.Parse("vault://secret/data/pg")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	backend, err := newVaultKVBackend(u, nil)
+	if err != nil {
+		t.Fatalf("newVaultKVBackend: %v", err)
+	}
+	got := backend.(*vaultKVBackend).path
+	if got != "secret/data/pg" {
+		t.Errorf("path = %q, want %q", got, "secret/data/pg")
+	}
+}
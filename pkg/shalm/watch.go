@@ -0,0 +1,206 @@
+package shalm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.starlark.net/starlark"
+)
+
+// WatchTrigger configures which file changes should cause a chart to be
+// re-applied, and how long to wait for a burst of related changes to settle
+// before doing so.
+type WatchTrigger struct {
+	Patterns []string      // glob patterns, relative to a watched dir, that trigger a reload, e.g. "*.star"
+	Ignore   []string      // glob patterns, relative to a watched dir, that are never watched, e.g. ".git"
+	Debounce time.Duration // how long to wait after the last matching event before reapplying
+}
+
+// WatchOptions configures chartImpl.Watch.
+type WatchOptions struct {
+	Triggers []WatchTrigger
+	// OnError is called, instead of aborting the watch loop, whenever a
+	// reapply triggered by a file or discovery change fails - e.g. a
+	// Starlark syntax error the user is mid-way through fixing. It defaults
+	// to printing FormatError(err) to stderr.
+	OnError func(err error)
+}
+
+// DefaultWatchOptions returns the WatchOptions used by `shalm watch` when the
+// caller does not supply a more specific configuration.
+func DefaultWatchOptions() *WatchOptions {
+	return &WatchOptions{
+		Triggers: []WatchTrigger{
+			{
+				Patterns: []string{"*.star", "*.yaml", "templates/*"},
+				Ignore:   []string{".git"},
+				Debounce: 300 * time.Millisecond,
+			},
+		},
+	}
+}
+
+func (o *WatchOptions) ignored(rel string) bool {
+	for _, t := range o.Triggers {
+		for _, pattern := range t.Ignore {
+			if globMatch(pattern, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchingTrigger returns the WatchTrigger that rel (a path relative to the
+// watched root the event occurred under) matches, if any.
+func (o *WatchOptions) matchingTrigger(rel string) (WatchTrigger, bool) {
+	for _, t := range o.Triggers {
+		for _, pattern := range t.Patterns {
+			if globMatch(pattern, rel) {
+				return t, true
+			}
+		}
+	}
+	return WatchTrigger{}, false
+}
+
+// globMatch matches rel, a slash-separated path relative to a watched root,
+// against pattern, crossing path separators where filepath.Match alone would not.
+func globMatch(pattern string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, filepath.Base(rel))
+		return ok
+	}
+	if dir := strings.TrimSuffix(pattern, "/*"); dir != pattern {
+		return rel == dir || strings.HasPrefix(rel, dir+"/")
+	}
+	ok, _ := filepath.Match(pattern, rel)
+	return ok
+}
+
+func defaultOnError(err error) {
+	fmt.Fprintln(os.Stderr, "shalm watch:", FormatError(err))
+}
+
+// Watch watches c.dir and its subcharts' dirs and re-applies the chart
+// against k whenever a matching file or DiscoverySource changes, until ctx
+// is cancelled; a failed reapply is reported via opts.OnError, not returned.
+func (c *chartImpl) Watch(ctx context.Context, k K8s, opts *WatchOptions) error {
+	if opts == nil {
+		opts = DefaultWatchOptions()
+	}
+	onError := opts.OnError
+	if onError == nil {
+		onError = defaultOnError
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	roots := []string{c.dir}
+	if err := c.eachSubChart(func(subChart *chartImpl) error {
+		roots = append(roots, subChart.dir)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, root := range roots {
+		if err := addRecursiveWatch(watcher, root, opts); err != nil {
+			return err
+		}
+	}
+
+	reload := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}
+	if err := c.watchDiscovery(ctx, notify); err != nil {
+		return err
+	}
+
+	var timer *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			root := watchRootFor(roots, event.Name)
+			rel, err := filepath.Rel(root, event.Name)
+			if err != nil {
+				rel = filepath.Base(event.Name)
+			}
+			if opts.ignored(rel) {
+				continue
+			}
+			trigger, ok := opts.matchingTrigger(rel)
+			if !ok {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(trigger.Debounce, notify)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-reload:
+			if err := c.Apply(&starlark.Thread{Name: "watch"}, k); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// watchRootFor returns the entry of roots that file was found under, picking
+// the longest (most specific) match so a subchart root takes precedence over
+// the parent chart's root.
+func watchRootFor(roots []string, file string) string {
+	best := ""
+	for _, root := range roots {
+		if root != file && !strings.HasPrefix(file, root+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return filepath.Dir(file)
+	}
+	return best
+}
+
+func addRecursiveWatch(watcher *fsnotify.Watcher, root string, opts *WatchOptions) error {
+	return filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		if rel != "." && opts.ignored(rel) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(file)
+	})
+}
@@ -0,0 +1,56 @@
+package shalm
+
+import "testing"
+
+func TestGlobMatchBaseNamePatternAtAnyDepth(t *testing.T) {
+	cases := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.star", "Chart.star", true},
+		{"*.star", "subcharts/redis/Chart.star", true},
+		{"*.yaml", "values.yaml", true},
+		{"templates/*", "templates/deployment.yaml", true},
+		{"templates/*", "templates/nested/deployment.yaml", true},
+		{"templates/*", "values.yaml", false},
+		{"*.star", "README.md", false},
+	}
+	for _, tc := range cases {
+		if got := globMatch(tc.pattern, tc.rel); got != tc.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tc.pattern, tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestMatchingTriggerNestedPaths(t *testing.T) {
+	opts := DefaultWatchOptions()
+	if _, ok := opts.matchingTrigger("templates/nested/deployment.yaml"); !ok {
+		t.Errorf("matchingTrigger() did not match a file nested under templates/")
+	}
+	if _, ok := opts.matchingTrigger("subcharts/redis/Chart.star"); !ok {
+		t.Errorf("matchingTrigger() did not match a subchart's Chart.star by basename")
+	}
+}
+
+func TestIgnoredMatchesGitDirAnyDepth(t *testing.T) {
+	opts := DefaultWatchOptions()
+	if !opts.ignored(".git") {
+		t.Errorf("ignored(\".git\") = false, want true")
+	}
+	if !opts.ignored("subcharts/redis/.git") {
+		t.Errorf("ignored(\"subcharts/redis/.git\") = false, want true")
+	}
+}
+
+func TestWatchRootForPicksMostSpecificRoot(t *testing.T) {
+	roots := []string{"/chart", "/chart/subcharts/redis"}
+	got := watchRootFor(roots, "/chart/subcharts/redis/Chart.star")
+	if got != "/chart/subcharts/redis" {
+		t.Errorf("watchRootFor() = %q, want the subchart root", got)
+	}
+	got = watchRootFor(roots, "/chart/values.yaml")
+	if got != "/chart" {
+		t.Errorf("watchRootFor() = %q, want the parent chart root", got)
+	}
+}